@@ -1,79 +1,430 @@
 package crontab
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Crontab 表示cron表的Crontab结构
 type Crontab struct {
-	ticker *time.Ticker
-	jobs   []job
+	loc *time.Location
+
+	mu       sync.Mutex
+	jobs     []*job
+	logger   Logger
+	wrappers []JobWrapper
+	running  bool
+	wg       *sync.WaitGroup
+
+	nextID EntryID
+	wake   chan struct{}
+	stop   chan struct{}
+}
+
+// EntryID 唯一标识一个通过AddJob注册的任务，由AddJob返回
+type EntryID int
+
+// Entry 是某个任务在调用Entries()时刻的只读快照
+type Entry struct {
+	ID       EntryID
+	Schedule string
+	Next     time.Time
+	Prev     time.Time
 }
 
 // job 在cron表中工作
 type job struct {
+	id       EntryID
+	spec     string
+	schedule schedule
+	due      time.Time
+	prev     time.Time
+
+	fn   interface{}
+	args []interface{}
+
+	cron     *Crontab
+	disabled bool
+
+	name                string
+	logger              Logger
+	recover             bool
+	skipIfStillRunning  bool
+	delayIfStillRunning bool
+
+	running int32         // guards skipIfStillRunning, accessed atomically
+	runLock chan struct{} // guards delayIfStillRunning, nil unless requested
+}
+
+// schedule 决定了一个job何时应该运行，cron表达式和@every间隔
+// 任务共用同一个抽象，以便在c.jobs中共存
+type schedule interface {
+	// next 返回from之后该调度下一次应该触发的时刻，如果在可预见的
+	// 未来内都不会触发（例如2月30日这种不可能的日期），返回零值时间
+	next(from time.Time) time.Time
+}
+
+// cronSchedule 是由cron表达式的六个字段解析出的调度
+type cronSchedule struct {
+	loc *time.Location
+
 	second    map[int]struct{}
 	min       map[int]struct{}
 	hour      map[int]struct{}
 	day       map[int]struct{}
 	month     map[int]struct{}
 	dayOfWeek map[int]struct{}
+}
 
-	fn   interface{}
-	args []interface{}
+// next 从from之后一秒开始，依次推进月、日、时、分、秒直到所有字段都
+// 匹配为止，任何字段被推进时都会把更低位的字段重置为最小值。搜索范围
+// 封顶5年，超出范围（例如表达式要求2月30日这种永远不会出现的日期）
+// 返回零值时间。计算始终在s.loc所表示的时区下进行，这对DST切换附近
+// 的日期/星期字段尤其重要
+func (s *cronSchedule) next(from time.Time) time.Time {
+	t := from.In(s.loc).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for !containsInt(s.month, int(t.Month())) {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !cronDayMatches(s, t) {
+		t = t.AddDate(0, 0, 1)
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for !containsInt(s.hour, t.Hour()) {
+		t = t.Add(time.Hour)
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for !containsInt(s.min, t.Minute()) {
+		t = t.Add(time.Minute)
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.loc)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for !containsInt(s.second, t.Second()) {
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// cronDayMatches 对日期和星期字段使用OR语义
+func cronDayMatches(s *cronSchedule, t time.Time) bool {
+	_, day := s.day[t.Day()]
+	_, dayOfWeek := s.dayOfWeek[int(t.Weekday())]
+	return day || dayOfWeek
+}
+
+// containsInt 报告v是否在允许的集合set中
+func containsInt(set map[int]struct{}, v int) bool {
+	_, ok := set[v]
+	return ok
+}
+
+// intervalSchedule 是由@every <duration>解析出的调度，它相对于
+// AddJob发生的时刻以固定间隔触发，而不进行日历字段匹配
+type intervalSchedule struct {
+	interval time.Duration
+	anchor   time.Time
 }
 
-// tick 是每分钟发生的单个任务
-type tick struct {
-	second    int
-	min       int
-	hour      int
-	day       int
-	month     int
-	dayOfWeek int
+// next 返回anchor之后、严格晚于from的下一个interval整数倍时刻
+func (s *intervalSchedule) next(from time.Time) time.Time {
+	if s.anchor.IsZero() {
+		return time.Time{}
+	}
+	if from.Before(s.anchor) {
+		return s.anchor
+	}
+	steps := from.Sub(s.anchor)/s.interval + 1
+	return s.anchor.Add(steps * s.interval)
 }
 
-// New 新的初始化并返回新的cron表
+// New 新的初始化并返回新的cron表，使用本地时区
 func New() *Crontab {
-	return new(time.Minute)
+	return NewInLocation(time.Local)
 }
 
-// new 创建了新的crontab，arg用于测试目的
-func new(t time.Duration) *Crontab {
+// NewInLocation 创建一个按照指定时区计算日程的cron表，这会影响
+// 月/日/星期字段的匹配以及夏令时切换附近的触发时间
+func NewInLocation(loc *time.Location) *Crontab {
 	c := &Crontab{
-		ticker: time.NewTicker(t),
+		loc:    loc,
+		logger: defaultLogger{},
+		wake:   make(chan struct{}, 1),
+	}
+
+	c.Start()
+
+	return c
+}
+
+// Location 返回该cron表用于计算日程的时区
+func (c *Crontab) Location() *time.Location {
+	return c.loc
+}
+
+// Running 报告调度器goroutine当前是否在运行
+func (c *Crontab) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.running
+}
+
+// Start 启动调度器goroutine，如果crontab已经在运行则什么都不做，
+// 这让Stop过的crontab可以重新启动。每次启动都会换上新的stop channel
+// 和WaitGroup，这样之前那一代调度器goroutine和在途任务不会与新一代
+// 产生交叉干扰
+func (c *Crontab) Start() {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	c.stop = stop
+	c.wg = &sync.WaitGroup{}
+	c.running = true
+	c.mu.Unlock()
+
+	go c.run(stop)
+}
+
+// Stop 停止调度器，此后不再有新的任务被触发。返回的context会在所有
+// 已经派发出去的go j.run()调用全部执行完毕后进入Done状态，
+// 供调用方在关闭期间优雅地等待正在运行的任务收尾
+func (c *Crontab) Stop() context.Context {
+	c.mu.Lock()
+	wg := c.wg
+	if c.running {
+		close(c.stop)
+		c.running = false
 	}
+	c.mu.Unlock()
 
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		for t := range c.ticker.C {
-			c.runScheduled(t)
+		if wg != nil {
+			wg.Wait()
 		}
+		cancel()
 	}()
+	return ctx
+}
 
-	return c
+// SetLogger 设置crontab级别的默认Logger，此后通过AddJob添加、且未通过
+// WithLogger覆盖的任务都会使用它记录开始/结束/耗时/panic。AddJob在添加
+// 任务时就确定了该任务的Logger，所以调用之前已经添加的任务继续使用
+// 原来的Logger
+func (c *Crontab) SetLogger(logger Logger) {
+	c.mu.Lock()
+	c.logger = logger
+	c.mu.Unlock()
+}
+
+// SetJobWrapper 用给定的wrapper链替换之前通过SetJobWrapper/Use设置的
+// 所有wrapper，wrapper按声明顺序由外向内包裹任务的执行
+func (c *Crontab) SetJobWrapper(wrappers ...JobWrapper) {
+	c.mu.Lock()
+	c.wrappers = append([]JobWrapper(nil), wrappers...)
+	c.mu.Unlock()
+}
+
+// Use 在已有的wrapper链末尾追加更多JobWrapper
+func (c *Crontab) Use(wrappers ...JobWrapper) {
+	c.mu.Lock()
+	c.wrappers = append(c.wrappers, wrappers...)
+	c.mu.Unlock()
+}
+
+// snapshotWrappers 返回当前wrapper链的拷贝，供job.run()在不持有c.mu的
+// 情况下安全地构建调用链
+func (c *Crontab) snapshotWrappers() []JobWrapper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wrappers := make([]JobWrapper, len(c.wrappers))
+	copy(wrappers, c.wrappers)
+	return wrappers
+}
+
+// defaultLoggerFor 返回c当前配置的默认Logger，用于未调用WithLogger的任务
+func (c *Crontab) defaultLoggerFor() Logger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.logger != nil {
+		return c.logger
+	}
+	return defaultLogger{}
 }
 
-// AddJob 到cron表
-func (c *Crontab) AddJob(schedule string, fn interface{}, args ...interface{}) error {
-	j, err := parseSchedule(schedule)
+// run 是调度器的主循环：睡眠到所有任务中最早的下一次触发时刻，
+// 到点后触发所有到期的任务并重新计算下一个唤醒点；AddJob/Clear
+// 等对c.jobs的修改通过wake channel立即唤醒循环以重新计算。stop是
+// Start()为这一代调度器创建的channel，只听自己这一份，不重新读取
+// c.stop字段，这样即使调用方紧接着再次Start()换上新channel，这个
+// goroutine也只会在它自己被Stop()时退出，不会和新一代并存。timer.C
+// 这个分支在拿到c.mu后会重新检查stop是否已被关闭，因为timer和Stop()
+// 可能同时准备就绪而timer赢得了select——这避免在Stop()返回之后还
+// 派发新任务，也避免在Stop()的goroutine已经在Wait()的WaitGroup上
+// 再次Add(1)
+func (c *Crontab) run(stop chan struct{}) {
+	for {
+		c.mu.Lock()
+		now := time.Now().In(c.loc)
+		next := c.earliestDue()
+		c.mu.Unlock()
+
+		if next.IsZero() {
+			select {
+			case <-c.wake:
+				continue
+			case <-stop:
+				return
+			}
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-timer.C:
+			c.mu.Lock()
+			stopped := false
+			select {
+			case <-stop:
+				// Stop()关闭了这一代的stop channel：timer在它和Stop()
+				// 之间赢得了select，但不能再派发新任务，否则会在
+				// Stop()的goroutine已经在Wait()的那个WaitGroup上Add(1)，
+				// 违反Stop()"此后不再有新的任务被触发"的约定
+				stopped = true
+			default:
+				c.fireDue(time.Now().In(c.loc))
+			}
+			c.mu.Unlock()
+			if stopped {
+				return
+			}
+		case <-c.wake:
+			timer.Stop()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// earliestDue 返回c.jobs中最早的due时间，调用者必须持有c.mu
+func (c *Crontab) earliestDue() time.Time {
+	var earliest time.Time
+	for _, j := range c.jobs {
+		if j.disabled || j.due.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || j.due.Before(earliest) {
+			earliest = j.due
+		}
+	}
+	return earliest
+}
+
+// fireDue 触发所有due时间已到达now的任务，并为它们计算下一次due时间，
+// 调用者必须持有c.mu
+func (c *Crontab) fireDue(now time.Time) {
+	for _, j := range c.jobs {
+		if j.disabled || j.due.IsZero() || j.due.After(now) {
+			continue
+		}
+		j.prev = j.due
+		c.dispatch(j)
+		j.due = j.schedule.next(j.due)
+	}
+}
+
+// dispatch 在新的goroutine中运行j，并用当前这一代的WaitGroup跟踪它
+// 直到完成，使Stop()返回的context能在所有在飞任务结束后才变为Done。
+// 调用者必须持有c.mu；wg在这里捕获成局部变量，这样即使调用方在任务
+// 还未结束时就又调用了Start()换上新一代WaitGroup，Done()依然配对到
+// Add()时所用的那一个。SkipIfStillRunning在这里、派发goroutine之前
+// 就被consult：被跳过的这次触发不会spawn goroutine，也不会碰WaitGroup。
+// DelayIfStillRunning不能在这里等待，否则会在持有c.mu的情况下阻塞整个
+// 调度循环，让其它任务也无法被派发，所以它仍在j.run()内部、新goroutine里
+// 排队等待上一次运行结束
+func (c *Crontab) dispatch(j *job) {
+	if j.skipIfStillRunning && !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		j.logger.Info("skip: job still running", "job", j.name)
+		return
+	}
+
+	wg := c.wg
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if j.skipIfStillRunning {
+			defer atomic.StoreInt32(&j.running, 0)
+		}
+		j.run()
+	}()
+}
+
+// wakeScheduler 唤醒调度器循环以重新计算下一次唤醒时刻，
+// 通道已满（已经有一次待处理的唤醒）时不阻塞
+func (c *Crontab) wakeScheduler() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// AddJob 到cron表，成功时返回可用于Entries/Remove等接口的EntryID。
+// args是传给fn本身的调用参数，fn没有参数时传nil或空切片；opts是
+// 结尾的变参，可以不传，也可以包含WithName/WithLogger/WithRecover/
+// WithSkipIfStillRunning/WithDelayIfStillRunning等Option来定制该任务
+// 的行为
+func (c *Crontab) AddJob(schedule string, fn interface{}, args []interface{}, opts ...Option) (EntryID, error) {
+	s, err := c.parseSchedule(schedule)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if fn == nil || reflect.ValueOf(fn).Kind() != reflect.Func {
-		return fmt.Errorf("Cron 必须是func（）")
+		return 0, fmt.Errorf("Cron 必须是func（）")
 	}
 
 	fnType := reflect.TypeOf(fn)
 	if len(args) != fnType.NumIn() {
-		return fmt.Errorf("func（）参数的数量和提供的参数的数量不匹配")
+		return 0, fmt.Errorf("func（）参数的数量和提供的参数的数量不匹配")
 	}
 
 	for i := 0; i < fnType.NumIn(); i++ {
@@ -83,96 +434,171 @@ func (c *Crontab) AddJob(schedule string, fn interface{}, args ...interface{}) e
 
 		if t1 != t2 {
 			if t1.Kind() != reflect.Interface {
-				return fmt.Errorf("Param with index %d shold be `%s` not `%s`", i, t1, t2)
+				return 0, fmt.Errorf("Param with index %d shold be `%s` not `%s`", i, t1, t2)
 			}
 			if !t2.Implements(t1) {
-				return fmt.Errorf("Param with index %d of type `%s` doesn't implement interface `%s`", i, t2, t1)
+				return 0, fmt.Errorf("Param with index %d of type `%s` doesn't implement interface `%s`", i, t2, t1)
 			}
 		}
 	}
 
-	// 全部选中，将作业添加到crontab
-	j.fn = fn
-	j.args = args
+	now := time.Now().In(c.loc)
+	if is, ok := s.(*intervalSchedule); ok {
+		is.anchor = now.Add(is.interval)
+	}
+
+	j := &job{
+		spec:     schedule,
+		schedule: s,
+		fn:       fn,
+		args:     args,
+		cron:     c,
+		name:     schedule,
+		recover:  true,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.logger == nil {
+		j.logger = c.defaultLoggerFor()
+	}
+	if j.delayIfStillRunning {
+		j.runLock = make(chan struct{}, 1)
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	j.id = c.nextID
+	j.due = s.next(now)
 	c.jobs = append(c.jobs, j)
-	return nil
+	c.mu.Unlock()
+
+	c.wakeScheduler()
+	return j.id, nil
 }
 
 // MustAddJob 就像AddJob，但如果作业有问题就会发生失败
-func (c *Crontab) MustAddJob(schedule string, fn interface{}, args ...interface{}) {
-	if err := c.AddJob(schedule, fn, args...); err != nil {
+func (c *Crontab) MustAddJob(schedule string, fn interface{}, args []interface{}, opts ...Option) EntryID {
+	id, err := c.AddJob(schedule, fn, args, opts...)
+	if err != nil {
 		panic(err)
 	}
+	return id
 }
 
-// Shutdown the cron table schedule
-func (c *Crontab) Shutdown() {
-	c.ticker.Stop()
+// Entries 返回当前crontab中所有任务的快照，包括各自的调度字符串、
+// 下一次计划运行时间和上一次运行时间
+func (c *Crontab) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]Entry, 0, len(c.jobs))
+	for _, j := range c.jobs {
+		entries = append(entries, Entry{
+			ID:       j.id,
+			Schedule: j.spec,
+			Next:     j.due,
+			Prev:     j.prev,
+		})
+	}
+	return entries
 }
 
 // Clear all jobs from cron table
 func (c *Crontab) Clear() {
-	c.jobs = []job{}
+	c.mu.Lock()
+	c.jobs = []*job{}
+	c.mu.Unlock()
+
+	c.wakeScheduler()
 }
 
-// RunAll jobs in cron table, shcheduled or not
-func (c *Crontab) RunAll() {
-	for _, j := range c.jobs {
-		go j.run()
+// Remove 从crontab中移除指定任务。移除在调度器的下一次唤醒时才会
+// 生效，不会中断或泄露已经在运行中的go j.run()调用
+func (c *Crontab) Remove(id EntryID) {
+	c.mu.Lock()
+	for i := range c.jobs {
+		if c.jobs[i].id == id {
+			c.jobs = append(c.jobs[:i], c.jobs[i+1:]...)
+			break
+		}
 	}
+	c.mu.Unlock()
+
+	c.wakeScheduler()
 }
 
-// RunScheduled jobs
-func (c *Crontab) runScheduled(t time.Time) {
-	tick := getTick(t)
-	for _, j := range c.jobs {
-		if j.tick(tick) {
-			go j.run()
+// Disable 让指定任务在重新Enable之前不再被触发，任务本身仍保留在
+// Entries()快照中
+func (c *Crontab) Disable(id EntryID) {
+	c.mu.Lock()
+	for i := range c.jobs {
+		if c.jobs[i].id == id {
+			c.jobs[i].disabled = true
+			break
 		}
 	}
+	c.mu.Unlock()
+
+	c.wakeScheduler()
 }
 
-// run the job using reflection
-func (j job) run() {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println("Crontab error", r)
+// Enable 恢复一个之前被Disable的任务，并从当前时刻重新计算它的
+// 下一次due时间，避免被禁用期间错过的触发一次性补上
+func (c *Crontab) Enable(id EntryID) {
+	c.mu.Lock()
+	now := time.Now().In(c.loc)
+	for _, j := range c.jobs {
+		if j.id == id {
+			j.disabled = false
+			j.due = j.schedule.next(now)
+			break
 		}
-	}()
-	v := reflect.ValueOf(j.fn)
-	rargs := make([]reflect.Value, len(j.args))
-	for i, a := range j.args {
-		rargs[i] = reflect.ValueOf(a)
 	}
-	v.Call(rargs)
-}
+	c.mu.Unlock()
 
-// tick decides should the job be lauhcned at the tick
-func (j job) tick(t tick) bool {
+	c.wakeScheduler()
+}
 
-	if _, ok := j.second[t.second]; !ok {
-		return false
-	}
+// RunAll jobs in cron table, shcheduled or not; jobs disabled via Disable
+// are skipped, consistent with Disable's "不再被触发" contract
+func (c *Crontab) RunAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if _, ok := j.min[t.min]; !ok {
-		return false
+	for _, j := range c.jobs {
+		if j.disabled {
+			continue
+		}
+		c.dispatch(j)
 	}
+}
 
-	if _, ok := j.hour[t.hour]; !ok {
-		return false
+// run 如果是DelayIfStillRunning任务，先排队等待上一次运行结束，然后把
+// invoke包裹在crontab的wrapper链（日志、panic恢复等）中执行。
+// SkipIfStillRunning已经在dispatch()里、派发这个goroutine之前consult过了
+func (j *job) run() {
+	if j.runLock != nil {
+		j.runLock <- struct{}{}
+		defer func() { <-j.runLock }()
 	}
 
-	_, day := j.day[t.day]
-	_, dayOfWeek := j.dayOfWeek[t.dayOfWeek]
-	if !day && !dayOfWeek {
-		return false
+	ws := j.cron.snapshotWrappers()
+	wrapped := loggingRecoverWrapper(j)
+	for i := len(ws) - 1; i >= 0; i-- {
+		wrapped = ws[i](wrapped)
 	}
+	wrapped()
+}
 
-	if _, ok := j.month[t.month]; !ok {
-		return false
+// invoke 使用反射调用底层的job函数
+func (j *job) invoke() {
+	v := reflect.ValueOf(j.fn)
+	rargs := make([]reflect.Value, len(j.args))
+	for i, a := range j.args {
+		rargs[i] = reflect.ValueOf(a)
 	}
-
-	return true
+	v.Call(rargs)
 }
 
 // 用于解析调度字符串的正则表达式
@@ -182,58 +608,115 @@ var (
 	matchRange  = regexp.MustCompile("^(\\d+)-(\\d+)$")
 )
 
-// parseSchedule 创建具有填充时间的作业结构以启动，或者如果synthax错误则创建错误
-func parseSchedule(s string) (j job, err error) {
+// predefined 将@开头的别名调度映射到等价的6字段cron表达式，
+// 语义与robfig/cron的预定义调度保持一致
+var predefined = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// monthNames 和 dayOfWeekNames 允许月份和星期字段使用英文缩写
+// 名称（不区分大小写），而不仅仅是数字
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayOfWeekNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// parseSchedule 创建具有填充时间的调度以启动，或者如果synthax错误则创建错误。
+// 除了标准的6字段cron表达式外，还接受@yearly/@monthly这类预定义别名
+// 以及@every <duration>这种固定间隔调度
+func (c *Crontab) parseSchedule(s string) (schedule, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "@every ") {
+		d, err := time.ParseDuration(strings.TrimPrefix(s, "@every "))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse duration %s in %s", strings.TrimPrefix(s, "@every "), s)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("@every duration must be positive, got %s in %s", strings.TrimPrefix(s, "@every "), s)
+		}
+		return &intervalSchedule{interval: d}, nil
+	}
+
+	if expanded, ok := predefined[strings.ToLower(s)]; ok {
+		s = expanded
+	} else if strings.HasPrefix(s, "@") {
+		return nil, fmt.Errorf("Unrecognized predefined schedule %s", s)
+	}
+
+	return c.parseCronSchedule(s)
+}
+
+// parseCronSchedule 解析标准的6字段cron表达式
+func (c *Crontab) parseCronSchedule(s string) (*cronSchedule, error) {
 	s = matchSpaces.ReplaceAllLiteralString(s, " ")
 	parts := strings.Split(s, " ")
 	if len(parts) != 6 {
-		return job{}, errors.New("Schedule string must have five components like * * * * *")
+		return nil, errors.New("Schedule string must have six components like * * * * * *")
 	}
 
-	j.second, err = parsePart(parts[0], 0, 59)
+	cs := &cronSchedule{loc: c.loc}
+	var err error
+
+	cs.second, err = parsePart(parts[0], 0, 59, nil)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
-	j.min, err = parsePart(parts[1], 0, 59)
+	cs.min, err = parsePart(parts[1], 0, 59, nil)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
-	j.hour, err = parsePart(parts[2], 0, 23)
+	cs.hour, err = parsePart(parts[2], 0, 23, nil)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
-	j.day, err = parsePart(parts[3], 1, 31)
+	cs.day, err = parsePart(parts[3], 1, 31, nil)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
-	j.month, err = parsePart(parts[4], 1, 12)
+	cs.month, err = parsePart(parts[4], 1, 12, monthNames)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
-	j.dayOfWeek, err = parsePart(parts[5], 0, 6)
+	cs.dayOfWeek, err = parsePart(parts[5], 0, 6, dayOfWeekNames)
 	if err != nil {
-		return j, err
+		return nil, err
 	}
 
 	switch {
-	case len(j.day) < 31 && len(j.dayOfWeek) == 7:
-		j.dayOfWeek = make(map[int]struct{})
-	case len(j.dayOfWeek) < 7 && len(j.day) == 31:
-		j.day = make(map[int]struct{})
+	case len(cs.day) < 31 && len(cs.dayOfWeek) == 7:
+		cs.dayOfWeek = make(map[int]struct{})
+	case len(cs.dayOfWeek) < 7 && len(cs.day) == 31:
+		cs.day = make(map[int]struct{})
 	default:
 
 	}
 
-	return j, nil
+	return cs, nil
 }
 
-// parsePart 从日程表字符串中解析单个日程表部分
-func parsePart(s string, min, max int) (map[int]struct{}, error) {
+// parsePart 从日程表字符串中解析单个日程表部分。如果names非空，
+// s中匹配到的名称（不区分大小写，如JAN或MON）会先被替换为对应的数字
+func parsePart(s string, min, max int, names map[string]int) (map[int]struct{}, error) {
+
+	if names != nil {
+		s = replaceNames(s, names)
+	}
 
 	r := make(map[int]struct{}, 0)
 
@@ -295,14 +778,11 @@ func parsePart(s string, min, max int) (map[int]struct{}, error) {
 	return r, nil
 }
 
-// getTick 从时间返回tick结构
-func getTick(t time.Time) tick {
-	return tick{
-		second:    t.Second(),
-		min:       t.Minute(),
-		hour:      t.Hour(),
-		day:       t.Day(),
-		month:     int(t.Month()),
-		dayOfWeek: int(t.Weekday()),
+// replaceNames 将s中出现的names键（不区分大小写）替换为对应的数值
+func replaceNames(s string, names map[string]int) string {
+	upper := strings.ToUpper(s)
+	for name, value := range names {
+		upper = strings.ReplaceAll(upper, name, strconv.Itoa(value))
 	}
+	return upper
 }
@@ -0,0 +1,95 @@
+package crontab
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Logger 用于记录任务生命周期事件，调用方可以接入自己的日志系统。
+// 未通过SetLogger/WithLogger显式设置时，crontab使用defaultLogger
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+	Error(err error, msg string, keysAndValues ...interface{})
+}
+
+// defaultLogger 把任务事件打印到标准库的log包
+type defaultLogger struct{}
+
+func (defaultLogger) Info(msg string, keysAndValues ...interface{}) {
+	log.Println(append([]interface{}{msg}, keysAndValues...)...)
+}
+
+func (defaultLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	log.Println(append([]interface{}{msg, err}, keysAndValues...)...)
+}
+
+// JobFunc 是一次任务触发时实际执行的可调用单元
+type JobFunc func()
+
+// JobWrapper 包裹一个JobFunc并返回附加了额外行为（日志、限流、
+// 恢复策略等）的JobFunc，用于组合成中间件链
+type JobWrapper func(JobFunc) JobFunc
+
+// Option 在AddJob时定制单个任务的行为
+type Option func(*job)
+
+// WithName 给任务起一个名字，用于日志输出；不设置时默认使用调度字符串
+func WithName(name string) Option {
+	return func(j *job) {
+		j.name = name
+	}
+}
+
+// WithLogger 为该任务单独设置Logger，覆盖crontab级别的默认Logger
+func WithLogger(logger Logger) Option {
+	return func(j *job) {
+		j.logger = logger
+	}
+}
+
+// WithRecover 控制该任务panic时是否被恢复并记录（默认true）。
+// 传入false会让panic继续向上传播；由于这发生在dispatch派发出的
+// goroutine里，一个未恢复的panic会让整个进程崩溃，而不只是终止
+// 这一个goroutine，调用方需要清楚这个后果再关闭默认的恢复行为
+func WithRecover(recover bool) Option {
+	return func(j *job) {
+		j.recover = recover
+	}
+}
+
+// WithSkipIfStillRunning 如果该任务的上一次触发仍在运行，本次触发会被
+// 直接跳过而不是排队等待
+func WithSkipIfStillRunning() Option {
+	return func(j *job) {
+		j.skipIfStillRunning = true
+	}
+}
+
+// WithDelayIfStillRunning 如果该任务的上一次触发仍在运行，本次触发会
+// 阻塞等待上一次运行结束后再执行，而不是跳过或并发执行
+func WithDelayIfStillRunning() Option {
+	return func(j *job) {
+		j.delayIfStillRunning = true
+	}
+}
+
+// loggingRecoverWrapper 是每个任务执行时最内层的JobFunc：记录开始、
+// 结束、耗时，并按j.recover决定panic是被记录下来还是继续传播
+func loggingRecoverWrapper(j *job) JobFunc {
+	return func() {
+		start := time.Now()
+		j.logger.Info("job started", "job", j.name)
+		defer func() {
+			if r := recover(); r != nil {
+				if !j.recover {
+					panic(r)
+				}
+				j.logger.Error(fmt.Errorf("%v", r), "job panicked", "job", j.name, "duration", time.Since(start))
+				return
+			}
+			j.logger.Info("job finished", "job", j.name, "duration", time.Since(start))
+		}()
+		j.invoke()
+	}
+}